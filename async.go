@@ -0,0 +1,143 @@
+package minilog
+
+// Asynchronous logging: println formats the header/message and hands the
+// record off to a background goroutine; the worker owns rotation, stderr
+// writes and flushing. This keeps the hot path (the caller's goroutine)
+// down to a single channel send.
+
+import "sync/atomic"
+
+// DropPolicy controls what happens when the async queue is full.
+type DropPolicy int
+
+const (
+	// DropBlock blocks the caller until the worker makes room (default).
+	DropBlock DropPolicy = iota
+	// DropOldest discards the oldest queued record to make room for the new one.
+	DropOldest
+	// DropNewest discards the incoming record, keeping the queue untouched.
+	DropNewest
+)
+
+type logRecord struct {
+	sev     severity
+	header  string
+	message string
+	rec     *Record
+	flush   chan struct{}
+}
+
+// SetAsync switches the logger to asynchronous mode: formatted records are
+// pushed onto a channel of size bufSize and a background goroutine performs
+// the actual rotation/flushing/I-O. Calling it twice is a no-op.
+func (l *Logger) SetAsync(bufSize int) {
+	if bufSize <= 0 {
+		bufSize = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if atomic.LoadInt32(&l.async) != 0 {
+		return
+	}
+
+	l.asyncCh = make(chan *logRecord, bufSize)
+	l.asyncDone = make(chan struct{})
+	atomic.StoreInt32(&l.async, 1)
+
+	go l.asyncWorker()
+}
+
+// SetDropPolicy sets the behavior used when the async queue is full.
+// It only has effect once SetAsync has been called.
+func (l *Logger) SetDropPolicy(p DropPolicy) {
+	l.dropPolicy = p
+}
+
+// DroppedCount returns the number of records discarded because the async
+// queue was full and the drop policy was DropOldest or DropNewest.
+func (l *Logger) DroppedCount() int64 {
+	return atomic.LoadInt64(&l.dropped)
+}
+
+// Sync blocks until every record enqueued so far has been written out.
+// It is a no-op when the logger is not in async mode.
+func (l *Logger) Sync() {
+	if atomic.LoadInt32(&l.async) == 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	l.asyncCh <- &logRecord{flush: done}
+	<-done
+}
+
+func (l *Logger) dispatch(sev severity, header, message string) {
+	if atomic.LoadInt32(&l.async) == 0 {
+		l.output(sev, header, message)
+		return
+	}
+
+	l.enqueue(&logRecord{sev: sev, header: header, message: message})
+}
+
+func (l *Logger) dispatchRecord(rec *Record) {
+	if atomic.LoadInt32(&l.async) == 0 {
+		l.outputRecord(rec)
+		return
+	}
+
+	l.enqueue(&logRecord{rec: rec})
+}
+
+func (l *Logger) enqueue(rec *logRecord) {
+	switch l.dropPolicy {
+	case DropOldest:
+		select {
+		case l.asyncCh <- rec:
+		default:
+			select {
+			case old := <-l.asyncCh:
+				if old.flush != nil {
+					// Never drop a Sync marker: requeue it behind the
+					// queue so its Sync still returns.
+					l.asyncCh <- old
+				} else {
+					atomic.AddInt64(&l.dropped, 1)
+				}
+			default:
+			}
+			select {
+			case l.asyncCh <- rec:
+			default:
+				atomic.AddInt64(&l.dropped, 1)
+			}
+		}
+	case DropNewest:
+		select {
+		case l.asyncCh <- rec:
+		default:
+			atomic.AddInt64(&l.dropped, 1)
+		}
+	default: // DropBlock
+		l.asyncCh <- rec
+	}
+}
+
+// asyncWorker owns everything println used to do inline: rotation, the
+// stderr/file writes and flushing. It runs until asyncCh is closed and
+// drained, then signals asyncDone so CloseLogger can finish up.
+func (l *Logger) asyncWorker() {
+	defer close(l.asyncDone)
+
+	for rec := range l.asyncCh {
+		switch {
+		case rec.flush != nil:
+			close(rec.flush)
+		case rec.rec != nil:
+			l.outputRecord(rec.rec)
+		default:
+			l.output(rec.sev, rec.header, rec.message)
+		}
+	}
+}
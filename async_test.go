@@ -0,0 +1,73 @@
+package minilog
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestPrintlnDedupRace exercises the exact pattern the race was reported
+// against: multiple goroutines calling into println (by way of Ltrace)
+// concurrently once SetAsync is active. Run with -race to verify prevLog
+// is no longer read/written unlocked.
+func TestPrintlnDedupRace(t *testing.T) {
+	l := InitLogger()
+	l.SetLogMode(0)
+	l.SetLogLevel("trace") // otherwise Ltrace is filtered before reaching prevLog
+	l.SetAsync(64)
+	defer CloseLogger()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				Ltrace("worker %d iteration %d", n, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	l.Sync()
+}
+
+// TestAsyncFlagRace exercises l.async itself: SetAsync flips it under l.mu
+// while dispatch/Sync read it without the lock, so this needs to be an
+// atomic load/store pair rather than a plain bool (caught by go test -race).
+func TestAsyncFlagRace(t *testing.T) {
+	l := InitLogger()
+	l.SetLogMode(0)
+	defer CloseLogger()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		l.SetAsync(16)
+	}()
+
+	for i := 0; i < 50; i++ {
+		l.dispatch(infoLevel, "hdr", "msg")
+		l.Sync()
+	}
+	wg.Wait()
+}
+
+func TestEnqueueDropPolicies(t *testing.T) {
+	// Exercise enqueue directly against a channel nobody drains, so the
+	// drop behavior is deterministic instead of racing a live worker.
+	l := &Logger{asyncCh: make(chan *logRecord, 1), dropPolicy: DropNewest}
+
+	l.enqueue(&logRecord{message: "first"})  // fills the only slot
+	l.enqueue(&logRecord{message: "second"}) // queue full: dropped
+
+	if got := l.DroppedCount(); got != 1 {
+		t.Fatalf("DroppedCount() = %d, want 1 under DropNewest with a saturated queue", got)
+	}
+
+	l.dropped = 0
+	l.dropPolicy = DropOldest
+	l.enqueue(&logRecord{message: "third"}) // evicts "first", queue still full
+	if got := l.DroppedCount(); got != 1 {
+		t.Fatalf("DroppedCount() = %d, want 1 under DropOldest evicting the queued record", got)
+	}
+}
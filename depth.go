@@ -0,0 +1,137 @@
+package minilog
+
+// Depth-aware entry points for wrappers that call into this package from a
+// helper function and want the logged caller to be their own caller rather
+// than the helper itself, plus drop-in compatibility with code already
+// built against the standard log package.
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+// formatHeaderDepth is formatHeader's depth-aware counterpart: depth 0
+// reports the direct caller of the LxxxDepth/LxxxDepthf function that
+// invoked it, matching the convention glog's InfoDepth uses.
+func (l *Logger) formatHeaderDepth(level string, depth int) string {
+	_, file, line := GetLogFileLine(depth + 3)
+	return formatHeaderLine(level, file, line)
+}
+
+func (l *Logger) printlnDepth(s severity, depth int, message string) {
+	if l.level > s && s < numSeverity {
+		return
+	}
+
+	header := l.formatHeaderDepth(severityName[s], depth)
+
+	// See println for why prevLog needs l.mu: SetAsync lets producer
+	// goroutines reach this dedup state concurrently.
+	l.mu.Lock()
+	if l.prevLog.repeatLog == message {
+		l.prevLog.repeatNum++
+		l.prevLog.lastHeader = header
+		l.mu.Unlock()
+		return
+	}
+	l.mu.Unlock()
+
+	l.printLastLog()
+
+	l.mu.Lock()
+	l.prevLog.repeatLog = message
+	l.prevLog.lastHeader = header
+	l.prevLog.lastSeverity = s
+	l.prevLog.repeatNum = 0
+	l.mu.Unlock()
+
+	l.dispatch(s, header, message)
+}
+
+// Trace, depth frames above the caller
+func LtraceDepth(depth int, args ...interface{}) {
+	logger.printlnDepth(traceLevel, depth, fmt.Sprint(args...))
+}
+
+// Debug, depth frames above the caller
+func LdebugDepth(depth int, args ...interface{}) {
+	logger.printlnDepth(debugLevel, depth, fmt.Sprint(args...))
+}
+
+// Info, depth frames above the caller
+func LinfoDepth(depth int, args ...interface{}) {
+	logger.printlnDepth(infoLevel, depth, fmt.Sprint(args...))
+}
+
+// Warning, depth frames above the caller
+func LwarnDepth(depth int, args ...interface{}) {
+	logger.printlnDepth(warnLevel, depth, fmt.Sprint(args...))
+}
+
+// Error, depth frames above the caller
+func LerrorDepth(depth int, args ...interface{}) {
+	logger.printlnDepth(errorLevel, depth, fmt.Sprint(args...))
+}
+
+// Fatal, depth frames above the caller
+func LfatalDepth(depth int, args ...interface{}) {
+	logger.printlnDepth(fatalLevel, depth, fmt.Sprint(args...))
+}
+
+// Trace, printf-style, depth frames above the caller
+func LtraceDepthf(depth int, format string, args ...interface{}) {
+	logger.printlnDepth(traceLevel, depth, fmt.Sprintf(format, args...))
+}
+
+// Debug, printf-style, depth frames above the caller
+func LdebugDepthf(depth int, format string, args ...interface{}) {
+	logger.printlnDepth(debugLevel, depth, fmt.Sprintf(format, args...))
+}
+
+// Info, printf-style, depth frames above the caller
+func LinfoDepthf(depth int, format string, args ...interface{}) {
+	logger.printlnDepth(infoLevel, depth, fmt.Sprintf(format, args...))
+}
+
+// Warning, printf-style, depth frames above the caller
+func LwarnDepthf(depth int, format string, args ...interface{}) {
+	logger.printlnDepth(warnLevel, depth, fmt.Sprintf(format, args...))
+}
+
+// Error, printf-style, depth frames above the caller
+func LerrorDepthf(depth int, format string, args ...interface{}) {
+	logger.printlnDepth(errorLevel, depth, fmt.Sprintf(format, args...))
+}
+
+// Fatal, printf-style, depth frames above the caller
+func LfatalDepthf(depth int, format string, args ...interface{}) {
+	logger.printlnDepth(fatalLevel, depth, fmt.Sprintf(format, args...))
+}
+
+// stdLogWriter adapts this package into the io.Writer a *log.Logger writes
+// through, so NewStandardLogger can hand libraries a drop-in *log.Logger.
+type stdLogWriter struct {
+	sev severity
+}
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	logger.println(w.sev, "%s", bytes.TrimRight(p, "\n"))
+	return len(p), nil
+}
+
+// NewStandardLogger returns a *log.Logger whose Write routes into this
+// package at sev, so code already built against the standard log package
+// (log.Printf and friends) can be pointed at this logger without changes.
+func NewStandardLogger(sev Severity) *log.Logger {
+	return log.New(stdLogWriter{sev: sev}, "", 0)
+}
+
+// Names returns the current active log file path for the info, warn and
+// error tiers. This package writes a single combined file rather than a
+// file per severity, so all three are the same path.
+func (l *Logger) Names() (info, warn, error string) {
+	name := filepath.Join(l.logDir, l.logName)
+	return name, name, name
+}
@@ -0,0 +1,69 @@
+package minilog
+
+import (
+	"strings"
+	"testing"
+)
+
+// callGetLogFileLine0 is a one-frame wrapper so depth 0 should report this
+// function's own call site, matching GetLogFileLine's documented
+// "depth 0 is the direct caller" convention.
+func callGetLogFileLine0() (string, string, int) {
+	return GetLogFileLine(0)
+}
+
+// callGetLogFileLine1 adds one extra frame, so depth 1 from inside it should
+// land back on its caller, exercising the depth-offset arithmetic that
+// LxxxDepth/LxxxDepthf rely on.
+func callGetLogFileLine1() (string, string, int) {
+	return wrapGetLogFileLine1()
+}
+
+func wrapGetLogFileLine1() (string, string, int) {
+	return GetLogFileLine(1)
+}
+
+func TestGetLogFileLineDepth(t *testing.T) {
+	fn0, file0, line0 := callGetLogFileLine0()
+	if !strings.HasSuffix(file0, "depth_test.go") {
+		t.Errorf("depth 0 file = %q, want depth_test.go", file0)
+	}
+	if fn0 != "callGetLogFileLine0" {
+		t.Errorf("depth 0 func = %q, want callGetLogFileLine0", fn0)
+	}
+
+	fn1, file1, line1 := callGetLogFileLine1()
+	if !strings.HasSuffix(file1, "depth_test.go") {
+		t.Errorf("depth 1 file = %q, want depth_test.go", file1)
+	}
+	if fn1 != "callGetLogFileLine1" {
+		t.Errorf("depth 1 func = %q, want callGetLogFileLine1", fn1)
+	}
+
+	// Every candidate frame lives in this file, so the function names are
+	// what pin the depth: off by one frame would report wrapGetLogFileLine1
+	// or this test function instead. The exact line numbers are too brittle
+	// to assert on; they are only checked to be resolved (positive).
+	if line0 <= 0 || line1 <= 0 {
+		t.Errorf("expected positive line numbers, got depth0=%d depth1=%d", line0, line1)
+	}
+}
+
+func TestNewStandardLoggerTrimsTrailingNewline(t *testing.T) {
+	l := InitLogger()
+	l.SetLogMode(0)
+	defer CloseLogger()
+
+	sink := &recordingSink{}
+	l.AddSink(sink, SeverityTrace)
+
+	std := NewStandardLogger(SeverityWarn)
+	std.Print("hello from the standard logger")
+
+	if len(sink.emitted) != 1 {
+		t.Fatalf("emitted = %v, want exactly one record", sink.emitted)
+	}
+	if sink.emitted[0] != "hello from the standard logger" {
+		t.Errorf("message = %q, want no trailing newline", sink.emitted[0])
+	}
+}
@@ -0,0 +1,222 @@
+package minilog
+
+// Structured logging: Linfow and friends build a Record and hand it to a
+// Formatter instead of going through callHeader/println, so the same
+// entry either renders as the usual text line or as a JSON object with
+// ts/level/caller/pid/host/msg fields suitable for an ELK/Loki pipeline.
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field is a single key/value pair attached to a structured log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Record is the structured form of a log entry passed to a Formatter.
+type Record struct {
+	Time    time.Time
+	Level   Severity
+	Caller  string
+	PID     int
+	Host    string
+	Message string
+	Fields  []Field
+}
+
+// Formatter renders a Record into buf, which is pooled and reused across
+// calls the same way the plain-text path reuses logBuffer, so a Formatter
+// should not retain buf past the call.
+type Formatter interface {
+	Format(buf *bytes.Buffer, rec *Record)
+}
+
+// SetFormatter installs the Formatter used by the Linfow-family structured
+// entry points. The plain Ltrace/Ldebug/.../Lfatal functions are unaffected.
+// Default is JSONFormatter.
+func (l *Logger) SetFormatter(f Formatter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.formatter = f
+}
+
+func (l *Logger) outputRecord(rec *Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buffer := l.getBuffer()
+	l.formatterLocked().Format(&buffer.Buffer, rec)
+	data := buffer.Bytes()
+
+	l.emitToSinks(rec.Level, rec.Caller, rec.Message, data)
+
+	l.putBuffer(buffer)
+}
+
+// formatterLocked returns the installed Formatter, or JSONFormatter when
+// none is set. Callers must hold l.mu.
+func (l *Logger) formatterLocked() Formatter {
+	if l.formatter == nil {
+		return JSONFormatter{}
+	}
+	return l.formatter
+}
+
+// TextFormatter renders a Record as the same "header: msg" line the plain
+// printf helpers produce, with any fields appended as "key=value".
+type TextFormatter struct{}
+
+func (TextFormatter) Format(buf *bytes.Buffer, rec *Record) {
+	year, month, day := rec.Time.Date()
+	hour, minute, second := rec.Time.Clock()
+	usec := rec.Time.Nanosecond() / 1000000
+
+	fmt.Fprintf(buf, "%04d-%02d-%02d %02d:%02d:%02d.%04d [%s] %d %s: %s",
+		year, month, day, hour, minute, second, usec,
+		severityName[rec.Level], rec.PID, rec.Caller, rec.Message)
+	for _, f := range rec.Fields {
+		fmt.Fprintf(buf, " %s=%v", f.Key, f.Value)
+	}
+	buf.WriteByte('\n')
+}
+
+// JSONFormatter renders a Record as a single-line JSON object with ts,
+// level, caller, pid, host, msg and the supplied fields.
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(buf *bytes.Buffer, rec *Record) {
+	buf.WriteByte('{')
+	writeJSONPair(buf, "ts", rec.Time.Format(time.RFC3339Nano), true)
+	writeJSONPair(buf, "level", strings.TrimSpace(severityName[rec.Level]), false)
+	writeJSONPair(buf, "caller", rec.Caller, false)
+
+	buf.WriteByte(',')
+	writeJSONString(buf, "pid")
+	buf.WriteByte(':')
+	buf.WriteString(strconv.Itoa(rec.PID))
+
+	writeJSONPair(buf, "host", rec.Host, false)
+	writeJSONPair(buf, "msg", rec.Message, false)
+
+	for _, f := range rec.Fields {
+		buf.WriteByte(',')
+		writeJSONString(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSONValue(buf, f.Value)
+	}
+	buf.WriteByte('}')
+	buf.WriteByte('\n')
+}
+
+func writeJSONPair(buf *bytes.Buffer, key, value string, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	writeJSONString(buf, key)
+	buf.WriteByte(':')
+	writeJSONString(buf, value)
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch val := v.(type) {
+	case string:
+		writeJSONString(buf, val)
+	case bool:
+		buf.WriteString(strconv.FormatBool(val))
+	case int:
+		buf.WriteString(strconv.Itoa(val))
+	case int64:
+		buf.WriteString(strconv.FormatInt(val, 10))
+	case float64:
+		buf.WriteString(strconv.FormatFloat(val, 'g', -1, 64))
+	case nil:
+		buf.WriteString("null")
+	case error:
+		writeJSONString(buf, val.Error())
+	case fmt.Stringer:
+		writeJSONString(buf, val.String())
+	default:
+		writeJSONString(buf, fmt.Sprint(val))
+	}
+}
+
+// Ltracew, Ldebugw, Linfow, Lwarnw, Lerrorw and Lfatalw log msg along with
+// an alternating key/value list through the active Formatter. A kv list
+// with an odd number of elements gets a synthetic "MISSING" value for the
+// trailing key.
+func Ltracew(msg string, kv ...interface{}) { logger.logw(traceLevel, msg, kv...) }
+func Ldebugw(msg string, kv ...interface{}) { logger.logw(debugLevel, msg, kv...) }
+func Linfow(msg string, kv ...interface{})  { logger.logw(infoLevel, msg, kv...) }
+func Lwarnw(msg string, kv ...interface{})  { logger.logw(warnLevel, msg, kv...) }
+func Lerrorw(msg string, kv ...interface{}) { logger.logw(errorLevel, msg, kv...) }
+func Lfatalw(msg string, kv ...interface{}) { logger.logw(fatalLevel, msg, kv...) }
+
+func (l *Logger) logw(s severity, msg string, kv ...interface{}) {
+	if l.level > s && s < numSeverity {
+		return
+	}
+
+	_, file, line := GetLogFileLine(2)
+	rec := &Record{
+		Time:    time.Now(),
+		Level:   s,
+		Caller:  fmt.Sprintf("%s[%d]", file, line),
+		PID:     pid,
+		Host:    host,
+		Message: msg,
+		Fields:  fieldsFromKV(kv),
+	}
+
+	l.dispatchRecord(rec)
+}
+
+func fieldsFromKV(kv []interface{}) []Field {
+	if len(kv) == 0 {
+		return nil
+	}
+
+	fields := make([]Field, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprintf("arg%d", i/2)
+		}
+		if i+1 < len(kv) {
+			fields = append(fields, Field{Key: key, Value: kv[i+1]})
+		} else {
+			fields = append(fields, Field{Key: key, Value: "MISSING"})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,72 @@
+package minilog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONFormatterEscaping(t *testing.T) {
+	rec := &Record{
+		Time:    time.Now(),
+		Level:   SeverityInfo,
+		Caller:  "file.go[1]",
+		PID:     123,
+		Host:    "host",
+		Message: "line one\nline two with a \"quote\" and a \\backslash",
+		Fields: []Field{
+			{Key: "err", Value: errBoom{}},
+			{Key: "count", Value: 3},
+		},
+	}
+
+	var buf bytes.Buffer
+	JSONFormatter{}.Format(&buf, rec)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\noutput: %s", err, buf.String())
+	}
+
+	if decoded["msg"] != rec.Message {
+		t.Errorf("msg = %q, want %q", decoded["msg"], rec.Message)
+	}
+	if decoded["err"] != "boom" {
+		t.Errorf("err field = %v, want %q", decoded["err"], "boom")
+	}
+	if decoded["count"] != float64(3) {
+		t.Errorf("count field = %v, want 3", decoded["count"])
+	}
+}
+
+type errBoom struct{}
+
+func (errBoom) Error() string { return "boom" }
+
+func TestFieldsFromKV(t *testing.T) {
+	cases := []struct {
+		name string
+		kv   []interface{}
+		want []Field
+	}{
+		{"empty", nil, nil},
+		{"even pairs", []interface{}{"a", 1, "b", 2}, []Field{{"a", 1}, {"b", 2}}},
+		{"odd trailing key gets MISSING", []interface{}{"a", 1, "b"}, []Field{{"a", 1}, {"b", "MISSING"}}},
+		{"non-string key falls back to positional name", []interface{}{1, "x"}, []Field{{"arg0", "x"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := fieldsFromKV(c.kv)
+			if len(got) != len(c.want) {
+				t.Fatalf("fieldsFromKV(%v) = %v, want %v", c.kv, got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Errorf("field[%d] = %+v, want %+v", i, got[i], c.want[i])
+				}
+			}
+		})
+	}
+}
@@ -47,9 +47,9 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -67,6 +67,19 @@ const (
 	numSeverity = 6
 )
 
+// Severity is the exported name for the logger's severity levels, used by
+// LogSink implementations living outside this package.
+type Severity = severity
+
+const (
+	SeverityTrace = traceLevel
+	SeverityDebug = debugLevel
+	SeverityInfo  = infoLevel
+	SeverityWarn  = warnLevel
+	SeverityError = errorLevel
+	SeverityFatal = fatalLevel
+)
+
 var severityName = []string{
 	traceLevel: "TRACE",
 	debugLevel: "DEBUG",
@@ -112,12 +125,31 @@ type Logger struct {
 	mu         sync.Mutex
 	writer     flushWriter
 	nBytes     int
+
+	async      int32 // 0/1, read/written via atomic so non-lock-holding readers (Sync, dispatch, close) are race-free
+	asyncCh    chan *logRecord
+	asyncDone  chan struct{}
+	dropPolicy DropPolicy
+	dropped    int64
+
+	modeFileSink   *fileSink
+	modeStderrSink *stderrSink
+	sinks          []sinkEntry
+
+	formatter Formatter
+
+	maxLines       int
+	lineCount      int
+	rotateInterval time.Duration
+	maxDays        int
+	timestampNames bool
 }
 
 type lastLog struct {
-	repeatLog  string
-	repeatNum  int
-	lastHeader string
+	repeatLog    string
+	repeatNum    int
+	lastHeader   string
+	lastSeverity severity
 }
 
 var (
@@ -171,6 +203,8 @@ func InitLogger() *Logger {
 	l.logName = program + ".log." + host
 	l.SetLogHeader(l.formatHeader)
 	l.nBytes = 0
+	l.modeFileSink = &fileSink{l: l}
+	l.modeStderrSink = &stderrSink{}
 	logger = l
 
 	return l
@@ -343,10 +377,15 @@ type logBuffer struct {
 	next *logBuffer
 }
 
-// get log filename, funcname and line number
+// GetLogFileLine returns the function name, file and line number of the
+// frame depth steps above its own caller (depth 0 is the direct caller of
+// GetLogFileLine), the same convention runtime.Caller itself uses one level
+// up. Wrappers that call into this package at a non-default stack depth
+// (see LinfoDepth and friends) must account for their own frames when
+// choosing depth; there is no implicit offset.
 func GetLogFileLine(depth int) (string, string, int) {
 	var funcName string
-	pc, file, line, ok := runtime.Caller(3 + depth)
+	pc, file, line, ok := runtime.Caller(depth + 1)
 	if !ok {
 		funcName = "unknow"
 		file = "???"
@@ -364,20 +403,23 @@ func GetLogFileLine(depth int) (string, string, int) {
 	return funcName, file, line
 }
 
-// log header by default
-func (l *Logger) formatHeader(level string) string {
+// formatHeaderLine renders the common "yy-mm-dd hh:mm:ss.uuuu level pid
+// file[line]" header shared by the default and depth-aware header builders.
+func formatHeaderLine(level, file string, line int) string {
 	now := time.Now()
 	year, month, day := now.Date()
 	hour, minute, second := now.Clock()
 	usec := now.Nanosecond() / 1000000
 
-	_, file, line := GetLogFileLine(2)
-	// yy-mm-dd hh:mm:ss.uuuu level pid file[line]:
-	header := fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d.%04d [%s] %d %s[%d]",
+	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d.%04d [%s] %d %s[%d]",
 		year, month, day, hour, minute, second, usec,
 		level, os.Getpid(), file, line)
+}
 
-	return header
+// log header by default
+func (l *Logger) formatHeader(level string) string {
+	_, file, line := GetLogFileLine(3)
+	return formatHeaderLine(level, file, line)
 }
 
 // Trace
@@ -418,37 +460,28 @@ func (l *Logger) println(s severity, format string, args ...interface{}) {
 	header := l.callHeader(severityName[s])
 	message := fmt.Sprintf(format, args...)
 
-	// equal prev log message
+	// prevLog is shared state read/written by every producer goroutine
+	// (that's the whole point of SetAsync), so it needs its own lock
+	// rather than relying on the caller's single-threaded use.
+	l.mu.Lock()
 	if l.prevLog.repeatLog == message {
 		l.prevLog.repeatNum++
 		l.prevLog.lastHeader = header
+		l.mu.Unlock()
 		return
-	} else {
-		l.printLastLog()
-		l.prevLog.repeatLog = message
-		l.prevLog.lastHeader = header
-		l.prevLog.repeatNum = 0
 	}
+	l.mu.Unlock()
 
-	l.output(header, message)
-}
+	l.printLastLog()
 
-// rename file when rolling policy
-func rename(fname, kname string) (string, int) {
-	var n int = 0
-	lenk := len(kname)
-	lenf := len(fname)
-	if lenk > lenf {
-		idx := kname[(lenf + 1):]
-		n, _ = strconv.Atoi(idx)
-	}
+	l.mu.Lock()
+	l.prevLog.repeatLog = message
+	l.prevLog.lastHeader = header
+	l.prevLog.lastSeverity = s
+	l.prevLog.repeatNum = 0
+	l.mu.Unlock()
 
-	name := fmt.Sprintf("%s.%d", fname, n+1)
-	err := os.Rename(kname, name)
-	if err != nil {
-		fmt.Println(err)
-	}
-	return name, n + 1
+	l.dispatch(s, header, message)
 }
 
 // create log file by default
@@ -473,7 +506,7 @@ func (l *Logger) createLogFile() *syncBuffer {
 		if i == 0 {
 			l.writer.Fclose()
 		}
-		name, n := rename(fname, l.keepName[i])
+		name, n := l.rename(fname, l.keepName[i])
 		l.keepName[n] = name
 	}
 
@@ -484,18 +517,12 @@ func (l *Logger) createLogFile() *syncBuffer {
 	}
 	sBuf.file = f
 	sBuf.Writer = bufio.NewWriterSize(f, 1024*1024)
-	l.createTime = getCreateTime()
+	l.createTime = l.nextCreateTime()
 	l.keepName[0] = fname
 
-	return sBuf
-}
+	l.pruneOldLogs()
 
-// get next create time in daily policy of rolling policy by default
-func getCreateTime() int64 {
-	timeStr := time.Now().Format("2006-01-02 00:00:00")
-	t, _ := time.Parse("2006-01-02 00:00:00", timeStr)
-	d, _ := time.ParseDuration("+24h")
-	return t.Add(d).Unix()
+	return sBuf
 }
 
 func isInToday(createTime int64) bool {
@@ -507,44 +534,58 @@ func isInToday(createTime int64) bool {
 
 	return false
 }
-func (l *Logger) output(header string, msg string) {
+func (l *Logger) output(sev severity, header string, msg string) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	buffer := l.getBuffer()
-	message := msg
 
 	buffer.WriteString(header)
 	buffer.WriteString(": ")
-	buffer.WriteString(message)
+	buffer.WriteString(msg)
 	buffer.WriteString("\n")
 	data := buffer.Bytes()
 
-	// log mode
+	l.emitToSinks(sev, header, msg, data)
+
+	l.putBuffer(buffer)
+}
+
+// emitToSinks fans a fully formatted record out to the built-in
+// stderr/file sinks (gated by SetLogMode) and every sink added via
+// AddSink (gated by its own minimum severity). Callers must hold l.mu.
+func (l *Logger) emitToSinks(sev severity, header, msg string, data []byte) {
 	if l.isLogStderrMode() {
-		os.Stdout.Write(data)
+		if err := l.modeStderrSink.Emit(sev, header, msg, data); err != nil {
+			fmt.Println(err)
+		}
 	}
 	if l.isLogFileMode() {
-		// rolling policy
-		// 1, file max size
-		// 2, daily
-		if l.writer == nil ||
-			(l.maxFileNum > 1 && ((l.maxSize == 0 && isInToday(l.createTime)) ||
-				(l.maxSize > 0 && l.nBytes > l.maxSize))) {
-			l.writer = l.createLogFile()
-			l.nBytes = 0
+		if err := l.modeFileSink.Emit(sev, header, msg, data); err != nil {
+			fmt.Println(err)
 		}
-		n, _ := l.writer.Write(data)
-		l.writer.Flush()
-		l.nBytes += n
 	}
 
-	l.putBuffer(buffer)
+	for _, e := range l.sinks {
+		if sev < e.min {
+			continue
+		}
+		if err := e.sink.Emit(sev, header, msg, data); err != nil {
+			fmt.Println(err)
+		}
+	}
 }
 
 func (l *Logger) printLastLog() {
-	if l.prevLog.repeatNum > 0 {
-		msg := fmt.Sprintf("Last message repeated %d times", l.prevLog.repeatNum)
-		l.output(l.prevLog.lastHeader, msg)
+	l.mu.Lock()
+	repeatNum := l.prevLog.repeatNum
+	lastSeverity := l.prevLog.lastSeverity
+	lastHeader := l.prevLog.lastHeader
+	l.prevLog.repeatNum = 0
+	l.mu.Unlock()
+
+	if repeatNum > 0 {
+		msg := fmt.Sprintf("Last message repeated %d times", repeatNum)
+		l.dispatch(lastSeverity, lastHeader, msg)
 	}
 }
 
@@ -556,9 +597,13 @@ func (l *Logger) putBuffer(b *logBuffer) {
 func (l *Logger) close() {
 	l.printLastLog()
 
-	if l.writer == nil {
-		return
+	if atomic.LoadInt32(&l.async) != 0 {
+		close(l.asyncCh)
+		<-l.asyncDone
+	}
+
+	l.modeFileSink.Close()
+	for _, e := range l.sinks {
+		e.sink.Close()
 	}
-	l.writer.Sync()
-	l.writer.Fclose()
 }
@@ -0,0 +1,161 @@
+package minilog
+
+// Rotation policy beyond the original max-size/daily pair: max-lines and
+// an arbitrary rotate interval, plus pruning rotated files by age instead
+// of only by count.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const rotateTimestampFormat = "2006-01-02T15-04-05"
+
+// SetMaxLines caps the number of lines written to the active log file
+// before it is rotated, in addition to any max-size/rotate-interval policy.
+func (l *Logger) SetMaxLines(n int) {
+	if n < 0 {
+		return
+	}
+	l.maxLines = n
+}
+
+// SetRotateInterval rotates the active log file every d, e.g. hourly or
+// every N minutes, in addition to any max-size/max-lines policy. It
+// replaces the default daily-on-zero policy once set.
+func (l *Logger) SetRotateInterval(d time.Duration) {
+	if d < 0 {
+		return
+	}
+	l.rotateInterval = d
+}
+
+// SetMaxDays deletes rotated log files older than days, determined by the
+// timestamp embedded in their name (see SetTimestampedRotation) rather than
+// by keepName's file count cap.
+func (l *Logger) SetMaxDays(days int) {
+	if days < 0 {
+		return
+	}
+	l.maxDays = days
+}
+
+// SetTimestampedRotation makes rotated file names embed the rotation time
+// ("program.log.host.2006-01-02T15-04-05.N") so multiple same-day
+// rotations don't collide when a max-size or max-lines policy is also
+// active, and so SetMaxDays has a date to prune by.
+func (l *Logger) SetTimestampedRotation(enable bool) {
+	l.timestampNames = enable
+}
+
+// needRotate reports whether the active log file should be rotated before
+// the next write.
+func (l *Logger) needRotate() bool {
+	if l.writer == nil {
+		return true
+	}
+	// maxLines/rotateInterval apply regardless of maxFileNum: unlike the
+	// original max-size/daily pair, they're new knobs a caller can reach
+	// for on their own, and gating them on maxFileNum>1 would make them
+	// silent no-ops under the default maxFileNum of 1.
+	if l.maxLines > 0 && l.lineCount >= l.maxLines {
+		return true
+	}
+	if l.rotateInterval > 0 && time.Now().Unix() >= l.createTime {
+		return true
+	}
+	if l.maxFileNum <= 1 {
+		return false
+	}
+	if l.maxSize > 0 && l.nBytes > l.maxSize {
+		return true
+	}
+	if l.maxSize == 0 && l.maxLines == 0 {
+		return isInToday(l.createTime)
+	}
+	return false
+}
+
+// nextCreateTime returns the Unix time at which the file just created
+// should next be rotated under the interval/daily policy.
+func (l *Logger) nextCreateTime() int64 {
+	if l.rotateInterval > 0 {
+		return time.Now().Add(l.rotateInterval).Unix()
+	}
+	return getCreateTime()
+}
+
+// get next create time in daily policy of rolling policy by default
+func getCreateTime() int64 {
+	timeStr := time.Now().Format("2006-01-02 00:00:00")
+	t, _ := time.Parse("2006-01-02 00:00:00", timeStr)
+	d, _ := time.ParseDuration("+24h")
+	return t.Add(d).Unix()
+}
+
+// rename file when rolling policy
+func (l *Logger) rename(fname, kname string) (string, int) {
+	n := 0
+	if len(kname) > len(fname) {
+		suffix := kname[len(fname)+1:]
+		if idx := strings.LastIndex(suffix, "."); idx >= 0 {
+			suffix = suffix[idx+1:]
+		}
+		if v, err := strconv.Atoi(suffix); err == nil {
+			n = v
+		}
+	}
+
+	var name string
+	if l.timestampNames {
+		name = fmt.Sprintf("%s.%s.%d", fname, time.Now().Format(rotateTimestampFormat), n+1)
+	} else {
+		name = fmt.Sprintf("%s.%d", fname, n+1)
+	}
+
+	if err := os.Rename(kname, name); err != nil {
+		fmt.Println(err)
+	}
+	return name, n + 1
+}
+
+// pruneOldLogs deletes rotated files under logDir whose embedded rotation
+// timestamp is older than maxDays. It is a no-op unless both maxDays and
+// SetTimestampedRotation are set, since the age can't be recovered from a
+// bare ".N" suffix.
+func (l *Logger) pruneOldLogs() {
+	if l.maxDays <= 0 || !l.timestampNames {
+		return
+	}
+
+	entries, err := os.ReadDir(l.logDir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -l.maxDays)
+	prefix := l.logName + "."
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		t, ok := parseRotatedTimestamp(e.Name()[len(prefix):])
+		if !ok || !t.Before(cutoff) {
+			continue
+		}
+		os.Remove(filepath.Join(l.logDir, e.Name()))
+	}
+}
+
+func parseRotatedTimestamp(suffix string) (time.Time, bool) {
+	for _, part := range strings.Split(suffix, ".") {
+		if t, err := time.ParseInLocation(rotateTimestampFormat, part, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,54 @@
+package minilog
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeWriter struct{}
+
+func (fakeWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (fakeWriter) Flush() error                { return nil }
+func (fakeWriter) Sync() error                 { return nil }
+func (fakeWriter) Fclose() error               { return nil }
+
+func TestNeedRotateMaxLines(t *testing.T) {
+	cases := []struct {
+		name       string
+		maxFileNum int
+		maxLines   int
+		lineCount  int
+		want       bool
+	}{
+		{"fires at default maxFileNum of 1", 1, 3, 3, true},
+		{"below threshold", 1, 3, 2, false},
+		{"also fires when maxFileNum > 1", 2, 3, 5, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l := &Logger{writer: fakeWriter{}, maxFileNum: c.maxFileNum, maxLines: c.maxLines, lineCount: c.lineCount}
+			if got := l.needRotate(); got != c.want {
+				t.Errorf("needRotate() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestNeedRotateInterval(t *testing.T) {
+	l := &Logger{
+		writer:         fakeWriter{},
+		maxFileNum:     1,
+		rotateInterval: time.Minute,
+		createTime:     time.Now().Add(-time.Second).Unix(),
+	}
+	if !l.needRotate() {
+		t.Fatal("expected rotation once rotateInterval has elapsed, even with maxFileNum <= 1")
+	}
+}
+
+func TestNeedRotateNoPolicyConfigured(t *testing.T) {
+	l := &Logger{writer: fakeWriter{}, maxFileNum: 1}
+	if l.needRotate() {
+		t.Fatal("no rotation policy set; needRotate should report false")
+	}
+}
@@ -0,0 +1,205 @@
+package minilog
+
+// LogSink lets output() fan a formatted record out to more than just the
+// built-in stderr/file writer, e.g. to ship logs to a collector in a
+// containerized environment.
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSink receives every record accepted by output or outputRecord, in
+// addition to whatever SetLogMode has enabled. raw is the fully formatted
+// line that the built-in sinks write verbatim: "header: msg\n" from the
+// printf helpers, or the active Formatter's output for the Linfow family,
+// whose header argument is the caller's "file[line]".
+type LogSink interface {
+	Emit(sev Severity, header, msg string, raw []byte) error
+	Flush() error
+	Close() error
+}
+
+type sinkEntry struct {
+	sink LogSink
+	min  Severity
+}
+
+// AddSink registers sink to receive every record at severity min or above.
+// Sinks registered this way are independent of SetLogMode, which only
+// controls the built-in stderr/file sinks.
+func (l *Logger) AddSink(sink LogSink, min Severity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, sinkEntry{sink: sink, min: min})
+}
+
+// RemoveSinks closes and unregisters every sink added via AddSink.
+func (l *Logger) RemoveSinks() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.sinks {
+		e.sink.Close()
+	}
+	l.sinks = nil
+}
+
+// fileSink is the built-in sink backing Mode ToFile: it rotates the active
+// file whenever needRotate says so (max-size/daily plus the max-lines and
+// rotate-interval policies) and writes through l.writer.
+type fileSink struct {
+	l *Logger
+}
+
+func (s *fileSink) Emit(sev Severity, header, msg string, raw []byte) error {
+	l := s.l
+	if l.needRotate() {
+		l.writer = l.createLogFile()
+		l.nBytes = 0
+		l.lineCount = 0
+	}
+	n, err := l.writer.Write(raw)
+	l.writer.Flush()
+	l.nBytes += n
+	l.lineCount++
+	return err
+}
+
+func (s *fileSink) Flush() error {
+	if s.l.writer == nil {
+		return nil
+	}
+	return s.l.writer.Flush()
+}
+
+func (s *fileSink) Close() error {
+	if s.l.writer == nil {
+		return nil
+	}
+	s.l.writer.Sync()
+	return s.l.writer.Fclose()
+}
+
+// stderrSink is the built-in sink backing Mode ToStderr.
+type stderrSink struct{}
+
+func (stderrSink) Emit(sev Severity, header, msg string, raw []byte) error {
+	_, err := os.Stdout.Write(raw)
+	return err
+}
+func (stderrSink) Flush() error { return nil }
+func (stderrSink) Close() error { return nil }
+
+// NetworkSink ships lines to a TCP or UDP collector. If the connection is
+// down, records are kept in a fixed-size local ring buffer and replayed in
+// order once the connection comes back, oldest-dropped-first if it never
+// does.
+type NetworkSink struct {
+	network string
+	addr    string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	ring    [][]byte
+	ringCap int
+	ringPos int
+	ringLen int
+}
+
+// NewNetworkSink dials network ("tcp" or "udp") at addr. Dial failures are
+// not fatal: records are buffered locally until a later Emit reconnects.
+func NewNetworkSink(network, addr string, ringCap int) *NetworkSink {
+	if ringCap <= 0 {
+		ringCap = 256
+	}
+	s := &NetworkSink{
+		network: network,
+		addr:    addr,
+		ringCap: ringCap,
+		ring:    make([][]byte, ringCap),
+	}
+	s.dial()
+	return s
+}
+
+func (s *NetworkSink) dial() {
+	conn, err := net.DialTimeout(s.network, s.addr, 2*time.Second)
+	if err == nil {
+		s.conn = conn
+	}
+}
+
+// Emit always queues line onto the ring first and drains it oldest-first,
+// so a line is never written ahead of backlog that was queued before it.
+// It returns an error only for an actual failure (the ring overflowing and
+// dropping a record); buffering while disconnected is the sink's normal,
+// working fallback and is not reported as an error.
+func (s *NetworkSink) Emit(sev Severity, header, msg string, raw []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line := make([]byte, len(raw))
+	copy(line, raw)
+
+	if s.conn == nil {
+		s.dial()
+	}
+
+	overflow := s.pushRing(line)
+
+	if s.conn != nil {
+		if err := s.drainRing(); err != nil {
+			s.conn.Close()
+			s.conn = nil
+		}
+	}
+
+	if overflow {
+		return fmt.Errorf("minilog: %s sink %s ring buffer overflowed, oldest record dropped", s.network, s.addr)
+	}
+	return nil
+}
+
+// pushRing appends line to the ring and reports whether it overwrote an
+// undelivered record because the ring was already full.
+func (s *NetworkSink) pushRing(line []byte) bool {
+	idx := (s.ringPos + s.ringLen) % s.ringCap
+	overflow := false
+	if s.ringLen == s.ringCap {
+		s.ringPos = (s.ringPos + 1) % s.ringCap
+		overflow = true
+	} else {
+		s.ringLen++
+	}
+	s.ring[idx] = line
+	return overflow
+}
+
+func (s *NetworkSink) drainRing() error {
+	for s.ringLen > 0 {
+		line := s.ring[s.ringPos]
+		if _, err := s.conn.Write(line); err != nil {
+			return err
+		}
+		s.ring[s.ringPos] = nil
+		s.ringPos = (s.ringPos + 1) % s.ringCap
+		s.ringLen--
+	}
+	return nil
+}
+
+func (s *NetworkSink) Flush() error { return nil }
+
+func (s *NetworkSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
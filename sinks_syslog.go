@@ -0,0 +1,41 @@
+//go:build !windows
+
+package minilog
+
+import "log/syslog"
+
+// SyslogSink ships records to the local syslog daemon via log/syslog,
+// which isn't available on Windows.
+type SyslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink opens a syslog connection tagged with tag.
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	w, err := syslog.New(syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{w: w}, nil
+}
+
+func (s *SyslogSink) Emit(sev Severity, header, msg string, raw []byte) error {
+	line := header + ": " + msg
+	switch sev {
+	case SeverityTrace, SeverityDebug:
+		return s.w.Debug(line)
+	case SeverityInfo:
+		return s.w.Info(line)
+	case SeverityWarn:
+		return s.w.Warning(line)
+	case SeverityError:
+		return s.w.Err(line)
+	case SeverityFatal:
+		return s.w.Crit(line)
+	default:
+		return s.w.Info(line)
+	}
+}
+
+func (s *SyslogSink) Flush() error { return nil }
+func (s *SyslogSink) Close() error { return s.w.Close() }
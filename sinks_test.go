@@ -0,0 +1,51 @@
+package minilog
+
+import "testing"
+
+// recordingSink collects every record it's handed, for asserting on what
+// AddSink actually delivers.
+type recordingSink struct {
+	emitted []string
+}
+
+func (s *recordingSink) Emit(sev Severity, header, msg string, raw []byte) error {
+	s.emitted = append(s.emitted, msg)
+	return nil
+}
+func (s *recordingSink) Flush() error { return nil }
+func (s *recordingSink) Close() error { return nil }
+
+func TestAddSinkFiltersBySeverity(t *testing.T) {
+	l := InitLogger()
+	l.SetLogMode(0)
+	defer CloseLogger()
+
+	sink := &recordingSink{}
+	l.AddSink(sink, SeverityWarn)
+
+	l.dispatch(infoLevel, "hdr", "below threshold")
+	l.dispatch(warnLevel, "hdr", "at threshold")
+	l.dispatch(errorLevel, "hdr", "above threshold")
+
+	if len(sink.emitted) != 2 {
+		t.Fatalf("emitted = %v, want 2 records at/above SeverityWarn", sink.emitted)
+	}
+	if sink.emitted[0] != "at threshold" || sink.emitted[1] != "above threshold" {
+		t.Fatalf("unexpected records delivered: %v", sink.emitted)
+	}
+}
+
+func TestRemoveSinksClosesAndUnregisters(t *testing.T) {
+	l := InitLogger()
+	l.SetLogMode(0)
+	defer CloseLogger()
+
+	sink := &recordingSink{}
+	l.AddSink(sink, SeverityTrace)
+	l.RemoveSinks()
+
+	l.dispatch(infoLevel, "hdr", "after removal")
+	if len(sink.emitted) != 0 {
+		t.Fatalf("sink received %v after RemoveSinks, want none", sink.emitted)
+	}
+}
@@ -0,0 +1,234 @@
+package minilog
+
+// V-level verbose logging, modeled on glog's V()/vmodule/log_backtrace_at.
+// Call sites do "if minilog.V(2) { minilog.V(2).Lvinfof(...) }"-style guards
+// via the Verbose bool itself, and the per-call-site enabled/disabled
+// decision is cached in vcache so that vmodule patterns are not
+// re-evaluated on every call. Every V call still resolves its caller's pc
+// with runtime.Caller first, so a disabled site costs that plus a sync.Map
+// lookup and atomic loads, not a single atomic load.
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Verbose is the result of a V() call. It implements Lvinfof so that
+// verbose logging reads as "V(2).Lvinfof(...)".
+type Verbose bool
+
+var (
+	verbosity    int32
+	vmoduleEpoch int32
+
+	vmoduleMu  sync.RWMutex
+	vmodule    []modulePattern
+	vcache     sync.Map // pc -> *vcacheEntry
+
+	backtraceMu sync.RWMutex
+	backtraceAt map[string]bool
+)
+
+type modulePattern struct {
+	pattern  string
+	literal  bool
+	fullPath bool
+	level    int32
+}
+
+type vcacheEntry struct {
+	epoch   int32
+	enabled int32
+}
+
+// SetVLevel sets the global verbosity threshold consulted by V when a call
+// site doesn't match any pattern registered with SetVModule.
+func SetVLevel(level int) {
+	atomic.StoreInt32(&verbosity, int32(level))
+	atomic.AddInt32(&vmoduleEpoch, 1)
+}
+
+// SetVModule installs per-module verbosity overrides from a comma-separated
+// list of pattern=level pairs, e.g. "file1=2,pkg/*=3,/full/path/to/foo.go=4".
+// A pattern without a leading "/" is glob-matched against the call site's
+// file basename (extension stripped); if it also contains a "/", it's
+// matched against that many trailing path segments instead (so "pkg/*"
+// matches any path ending ".../pkg/<file>.go" but not ".../otherpkg/x.go").
+// A leading "/" matches the full path, extension included.
+func SetVModule(spec string) error {
+	var pats []modulePattern
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("minilog: malformed vmodule entry %q", entry)
+		}
+		level, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return fmt.Errorf("minilog: malformed vmodule level in %q: %v", entry, err)
+		}
+
+		pattern := parts[0]
+		fullPath := strings.HasPrefix(pattern, "/")
+		if fullPath {
+			pattern = pattern[1:]
+		} else {
+			pattern = strings.TrimSuffix(pattern, ".go")
+		}
+
+		pats = append(pats, modulePattern{
+			pattern:  pattern,
+			literal:  !strings.ContainsAny(pattern, `*?[]`),
+			fullPath: fullPath,
+			level:    int32(level),
+		})
+	}
+
+	vmoduleMu.Lock()
+	vmodule = pats
+	vmoduleMu.Unlock()
+	atomic.AddInt32(&vmoduleEpoch, 1)
+	return nil
+}
+
+// SetBacktraceAt installs a "-log_backtrace_at"-style list of file:line
+// locations. When a V() call site matches, Lvinfof appends a stack dump to
+// the logged message.
+func SetBacktraceAt(spec string) error {
+	locs := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, ":") {
+			return fmt.Errorf("minilog: malformed log_backtrace_at entry %q", entry)
+		}
+		locs[entry] = true
+	}
+
+	backtraceMu.Lock()
+	backtraceAt = locs
+	backtraceMu.Unlock()
+	return nil
+}
+
+func (p modulePattern) match(file string) bool {
+	file = filepath.ToSlash(file)
+
+	var target string
+	switch {
+	case p.fullPath:
+		target = strings.TrimPrefix(file, "/")
+	case strings.Contains(p.pattern, "/"):
+		// Directory-aware pattern: compare against the same number of
+		// trailing path segments as the pattern has, so "pkg/*" matches
+		// ".../pkg/file.go" without also matching ".../otherpkg/file.go".
+		target = lastPathSegments(strings.TrimSuffix(file, ".go"), strings.Count(p.pattern, "/")+1)
+	default:
+		target = strings.TrimSuffix(filepath.Base(file), ".go")
+	}
+
+	if p.literal {
+		return target == p.pattern
+	}
+	matched, _ := filepath.Match(p.pattern, target)
+	return matched
+}
+
+// lastPathSegments returns the last n "/"-separated segments of path.
+func lastPathSegments(path string, n int) string {
+	segs := strings.Split(path, "/")
+	if n > len(segs) {
+		n = len(segs)
+	}
+	return strings.Join(segs[len(segs)-n:], "/")
+}
+
+func vEnabledAt(file string, level int) bool {
+	vmoduleMu.RLock()
+	pats := vmodule
+	vmoduleMu.RUnlock()
+
+	for _, p := range pats {
+		if p.match(file) {
+			return int32(level) <= p.level
+		}
+	}
+
+	return int32(level) <= atomic.LoadInt32(&verbosity)
+}
+
+// V reports whether verbose logging is enabled for the calling site at the
+// given level, honoring any SetVModule override for that file. The decision
+// is cached per call site (by program counter) and only recomputed when
+// SetVLevel/SetVModule bump the epoch, so a disabled site costs a
+// runtime.Caller call, a cache lookup and atomic loads rather than a
+// pattern scan.
+func V(level int) Verbose {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return Verbose(int32(level) <= atomic.LoadInt32(&verbosity))
+	}
+
+	epoch := atomic.LoadInt32(&vmoduleEpoch)
+	if v, ok := vcache.Load(pc); ok {
+		entry := v.(*vcacheEntry)
+		if atomic.LoadInt32(&entry.epoch) == epoch {
+			return Verbose(atomic.LoadInt32(&entry.enabled) != 0)
+		}
+	}
+
+	file := "???"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		file, _ = fn.FileLine(pc)
+	}
+
+	entry := &vcacheEntry{epoch: epoch}
+	if vEnabledAt(file, level) {
+		entry.enabled = 1
+	}
+	vcache.Store(pc, entry)
+
+	return Verbose(entry.enabled != 0)
+}
+
+// Lvinfof logs a verbose info-level message if v is true, matching the
+// level the caller guarded with V(). If the call site is registered via
+// SetBacktraceAt, a stack dump is appended to the message.
+func (v Verbose) Lvinfof(format string, args ...interface{}) {
+	if !v || logger == nil {
+		return
+	}
+
+	message := fmt.Sprintf(format, args...)
+	if _, file, line, ok := runtime.Caller(1); ok && isBacktraceAt(file, line) {
+		message += "\n" + string(stackTrace())
+	}
+
+	logger.println(infoLevel, "%s", message)
+}
+
+func isBacktraceAt(file string, line int) bool {
+	backtraceMu.RLock()
+	defer backtraceMu.RUnlock()
+	if len(backtraceAt) == 0 {
+		return false
+	}
+	key := filepath.Base(file) + ":" + strconv.Itoa(line)
+	return backtraceAt[key]
+}
+
+func stackTrace() []byte {
+	buf := make([]byte, 1<<16)
+	n := runtime.Stack(buf, false)
+	return buf[:n]
+}
@@ -0,0 +1,160 @@
+package minilog
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestModulePatternMatch(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"basename literal", "foo", "/src/pkg/foo.go", true},
+		{"basename literal wrong dir is irrelevant", "foo", "/other/foo.go", true},
+		{"basename glob", "foo*", "/src/pkg/foobar.go", true},
+		{"directory-aware glob matches its package", "pkg/*", "/src/pkg/foo.go", true},
+		{"directory-aware glob rejects a different package", "pkg/*", "/src/otherpkg/foo.go", false},
+		{"directory-aware literal", "pkg/foo", "/src/pkg/foo.go", true},
+		{"full path requires exact match", "/src/pkg/foo.go", "/src/pkg/foo.go", true},
+		{"full path rejects a different tree", "/src/pkg/foo.go", "/other/pkg/foo.go", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var pats []modulePattern
+			for _, p := range parseVModuleSpec(t, c.pattern+"=1") {
+				pats = append(pats, p)
+			}
+			if len(pats) != 1 {
+				t.Fatalf("expected exactly one parsed pattern, got %d", len(pats))
+			}
+			if got := pats[0].match(c.file); got != c.want {
+				t.Errorf("match(%q) against pattern %q = %v, want %v", c.file, c.pattern, got, c.want)
+			}
+		})
+	}
+}
+
+// parseVModuleSpec drives SetVModule and hands back the parsed patterns so
+// match can be exercised directly without going through vEnabledAt's cache.
+func parseVModuleSpec(t *testing.T, spec string) []modulePattern {
+	t.Helper()
+	if err := SetVModule(spec); err != nil {
+		t.Fatalf("SetVModule(%q) error: %v", spec, err)
+	}
+	vmoduleMu.RLock()
+	defer vmoduleMu.RUnlock()
+	return append([]modulePattern(nil), vmodule...)
+}
+
+// callVAtLevel2/3 give V() a fixed call site (the pc V() caches against) so
+// the cache can be exercised across repeated calls from the same place.
+// They are kept out of line: an inlined copy at each call would give every
+// call its own pc and never hit a cached entry.
+//
+//go:noinline
+func callVAtLevel2() Verbose { return V(2) }
+
+//go:noinline
+func callVAtLevel3() Verbose { return V(3) }
+
+func TestVCacheInvalidatesOnSetVLevel(t *testing.T) {
+	defer SetVLevel(0)
+	SetVLevel(0)
+
+	if bool(callVAtLevel2()) {
+		t.Fatal("V(2) enabled at verbosity 0")
+	}
+	// Same call site, cached as disabled; SetVLevel must bump the epoch so
+	// this doesn't just replay the stale cached decision.
+	if bool(callVAtLevel2()) {
+		t.Fatal("V(2) enabled at verbosity 0 (second call)")
+	}
+
+	SetVLevel(2)
+	if !bool(callVAtLevel2()) {
+		t.Fatal("V(2) still disabled after SetVLevel(2); cache not invalidated")
+	}
+
+	SetVLevel(0)
+	if bool(callVAtLevel2()) {
+		t.Fatal("V(2) still enabled after SetVLevel(0); cache not invalidated")
+	}
+}
+
+func TestVCacheInvalidatesOnSetVModule(t *testing.T) {
+	defer SetVLevel(0)
+	defer SetVModule("")
+	SetVLevel(0)
+	SetVModule("")
+
+	if bool(callVAtLevel3()) {
+		t.Fatal("V(3) enabled with no vmodule override and verbosity 0")
+	}
+
+	if err := SetVModule("vlog_test=3"); err != nil {
+		t.Fatalf("SetVModule error: %v", err)
+	}
+	if !bool(callVAtLevel3()) {
+		t.Fatal("V(3) still disabled after a matching SetVModule override; cache not invalidated")
+	}
+}
+
+// callLvinfofWithBacktrace registers its own Lvinfof call site (two source
+// lines below the runtime.Caller(0) here) with SetBacktraceAt and then
+// invokes Lvinfof, so the call is guaranteed to match regardless of where
+// the test calling this helper lives. Keep exactly one statement between
+// the Caller(0) line and the Lvinfof call if this is ever edited.
+func callLvinfofWithBacktrace(t *testing.T, v Verbose, msg string) {
+	t.Helper()
+	_, file, line, _ := runtime.Caller(0)
+	_ = SetBacktraceAt(filepath.Base(file) + ":" + strconv.Itoa(line+2))
+	v.Lvinfof("%s", msg)
+}
+
+func TestLvinfofAppendsBacktraceOnMatch(t *testing.T) {
+	l := InitLogger()
+	l.SetLogMode(0)
+	l.SetLogLevel("trace") // Lvinfof logs at info; the default level is warn
+	defer CloseLogger()
+	defer SetBacktraceAt("")
+
+	sink := &recordingSink{}
+	l.AddSink(sink, SeverityTrace)
+
+	callLvinfofWithBacktrace(t, Verbose(true), "hello")
+
+	if len(sink.emitted) != 1 {
+		t.Fatalf("emitted = %v, want exactly one record", sink.emitted)
+	}
+	if !strings.Contains(sink.emitted[0], "goroutine") {
+		t.Errorf("message = %q, want a stack dump appended", sink.emitted[0])
+	}
+}
+
+func TestLvinfofSkipsBacktraceWithoutMatch(t *testing.T) {
+	l := InitLogger()
+	l.SetLogMode(0)
+	l.SetLogLevel("trace") // Lvinfof logs at info; the default level is warn
+	defer CloseLogger()
+	defer SetBacktraceAt("")
+
+	sink := &recordingSink{}
+	l.AddSink(sink, SeverityTrace)
+	SetBacktraceAt("")
+
+	Verbose(true).Lvinfof("hello")
+
+	if len(sink.emitted) != 1 {
+		t.Fatalf("emitted = %v, want exactly one record", sink.emitted)
+	}
+	if strings.Contains(sink.emitted[0], "goroutine") {
+		t.Errorf("message = %q, want no stack dump without a log_backtrace_at match", sink.emitted[0])
+	}
+}